@@ -3,6 +3,7 @@ package backend
 import (
 	"net"
 
+	"github.com/noironetworks/cilium-net/common/completion"
 	"github.com/noironetworks/cilium-net/common/ipam"
 	"github.com/noironetworks/cilium-net/common/types"
 
@@ -20,6 +21,13 @@ type bpfBackend interface {
 	EndpointSave(ep types.Endpoint) error
 	EndpointLabelsGet(epID uint16) (*types.OpLabels, error)
 	EndpointLabelsUpdate(epID uint16, op types.LabelOP, labels types.Labels) error
+
+	// ChainedEndpointJoin is EndpointJoin's counterpart for CNI add-on/
+	// chaining mode (see the "cni-chaining-mode" option accepted by
+	// control.Update): it attaches to an interface another CNI plugin has
+	// already provisioned instead of creating it. This package only
+	// defines the contract; the concrete implementation lives elsewhere.
+	ChainedEndpointJoin(ep types.Endpoint, existingIfName string, existingIPs []net.IP) error
 }
 
 type ipamBackend interface {
@@ -44,12 +52,54 @@ type policyBackend interface {
 	PolicyCanConsume(ctx *types.SearchContext) (*types.SearchContextReply, error)
 }
 
+// clusterMeshBackend is the interface for ClusterMesh, Cilium's multi-cluster
+// federation mechanism. This package only defines the contract; the
+// concrete daemon implementation that watches remote clusters and merges
+// their state into the local labelBackend/bpfBackend views lives elsewhere.
+type clusterMeshBackend interface {
+	AddCluster(name string, cfg types.ClusterConfig) error
+	RemoveCluster(name string) error
+	GetClusters() ([]string, error)
+	GetRemoteEndpoint(cluster string, epID uint16) (*types.Endpoint, error)
+	ResolveRemoteIdentity(cluster string, sha256 string) (*types.SecCtxLabel, error)
+}
+
 type control interface {
 	Ping() (*types.PingResponse, error)
 	Update(opts types.OptionMap) error
 	SyncState(path string, clean bool) error
 }
 
+// controllerBackend lets callers register named, periodic reconciliation
+// jobs instead of running ad-hoc goroutines, in place of a concrete
+// controller manager implementation. This package only defines the
+// contract; the manager that actually runs and retries those jobs lives
+// elsewhere.
+type controllerBackend interface {
+	UpdateController(name string, params types.ControllerParams) error
+	RemoveController(name string) error
+	GetControllerStatuses() ([]types.ControllerStatus, error)
+}
+
+// proxyBackend lets policyBackend.PolicyAdd install L7 rules (HTTP
+// method/path, gRPC service, Kafka topic) by delegating to an L7 proxy
+// managed by the daemon. This package only defines the contract; the
+// concrete proxy implementation lives elsewhere.
+type proxyBackend interface {
+	UpdateNetworkPolicy(ep types.Endpoint, policy *types.L7Policy, wg *completion.WaitGroup) error
+	RemoveNetworkPolicy(ep types.Endpoint) error
+	AckProxyPort(name string) (uint16, error)
+}
+
+// healthBackend exposes per-endpoint health so operators can query it
+// without shelling into pods. This package only defines the contract; the
+// concrete implementation that aggregates endpoint state into
+// types.EndpointStatus lives elsewhere.
+type healthBackend interface {
+	EndpointStatusGet(epID uint16) (*types.EndpointStatus, error)
+	EndpointStatusLogAdd(epID uint16, entry types.StatusLogEntry) error
+}
+
 type ui interface {
 	GetUIIP() (*net.TCPAddr, error)
 	RegisterUIListener(conn *websocket.Conn) (chan types.UIUpdateMsg, error)
@@ -59,6 +109,7 @@ type ui interface {
 type CiliumBackend interface {
 	bpfBackend
 	control
+	controllerBackend
 	ipamBackend
 	labelBackend
 	policyBackend
@@ -68,4 +119,7 @@ type CiliumBackend interface {
 type CiliumDaemonBackend interface {
 	CiliumBackend
 	ui
+	clusterMeshBackend
+	proxyBackend
+	healthBackend
 }
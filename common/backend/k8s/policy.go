@@ -0,0 +1,97 @@
+// Package k8s provides a policyBackend implementation that stores Cilium
+// policy trees in a path-keyed in-memory map, guarded by a mutex and
+// exposing a revision counter callers can poll. It does not yet talk to
+// Kubernetes: PolicyAdd/PolicyGet/PolicyDelete take the same arguments a
+// CRD-backed implementation eventually would, but there is no informer,
+// CRD client, or translation to/from a CiliumNetworkPolicy resource here.
+package k8s
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/noironetworks/cilium-net/common/types"
+)
+
+// PolicyBackend implements the policyBackend interface on top of a plain
+// map from path to *types.PolicyNode, guarded by mutex. It bumps revision
+// on every PolicyAdd/PolicyDelete so that callers waiting for a given
+// update to be visible can poll Revision.
+type PolicyBackend struct {
+	mutex sync.RWMutex
+
+	// nodes stores the policy tree, indexed by path
+	nodes map[string]*types.PolicyNode
+
+	// revision is bumped on every PolicyAdd/PolicyDelete, so that
+	// callers can wait for a given revision to be realized
+	revision uint64
+}
+
+// NewPolicyBackend creates a PolicyBackend with an empty policy tree.
+func NewPolicyBackend() *PolicyBackend {
+	return &PolicyBackend{
+		nodes: map[string]*types.PolicyNode{},
+	}
+}
+
+// PolicyAdd stores node under path, bumping the backend's revision.
+func (b *PolicyBackend) PolicyAdd(path string, node *types.PolicyNode) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.nodes[path] = node
+	b.revision++
+
+	return nil
+}
+
+// PolicyDelete removes the policy node stored under path.
+func (b *PolicyBackend) PolicyDelete(path string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.nodes[path]; !ok {
+		return fmt.Errorf("policy node %q not found", path)
+	}
+
+	delete(b.nodes, path)
+	b.revision++
+
+	return nil
+}
+
+// PolicyGet returns the policy node currently stored under path.
+func (b *PolicyBackend) PolicyGet(path string) (*types.PolicyNode, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	node, ok := b.nodes[path]
+	if !ok {
+		return nil, fmt.Errorf("policy node %q not found", path)
+	}
+
+	return node, nil
+}
+
+// PolicyCanConsume evaluates ctx against the stored policy tree. It is
+// intentionally implemented in terms of PolicyGet so that it shares the
+// exact same tree PolicyAdd/PolicyDelete keep up to date.
+func (b *PolicyBackend) PolicyCanConsume(ctx *types.SearchContext) (*types.SearchContextReply, error) {
+	root, err := b.PolicyGet("")
+	if err != nil {
+		return nil, err
+	}
+
+	return root.Allows(ctx), nil
+}
+
+// Revision returns the current policy tree revision, bumped on every
+// PolicyAdd/PolicyDelete. Callers waiting for an update to be realized can
+// poll this against the revision they observed before calling PolicyAdd.
+func (b *PolicyBackend) Revision() uint64 {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.revision
+}
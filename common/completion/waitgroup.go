@@ -0,0 +1,47 @@
+// Package completion provides a WaitGroup that callers can use to block
+// until an asynchronous operation handled by another component (e.g. the
+// Envoy proxy) has been acknowledged.
+package completion
+
+import "sync"
+
+// WaitGroup is like a sync.WaitGroup, but each added completion can also
+// be signalled as failed via Done(err), allowing the waiter to learn
+// whether the asynchronous operation it is waiting on succeeded.
+type WaitGroup struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewWaitGroup creates a new, empty WaitGroup.
+func NewWaitGroup() *WaitGroup {
+	return &WaitGroup{}
+}
+
+// Add registers one more completion to wait for.
+func (w *WaitGroup) Add() {
+	w.wg.Add(1)
+}
+
+// Done marks one completion as finished. A non-nil err marks it as failed.
+func (w *WaitGroup) Done(err error) {
+	if err != nil {
+		w.mu.Lock()
+		w.errs = append(w.errs, err)
+		w.mu.Unlock()
+	}
+	w.wg.Done()
+}
+
+// Wait blocks until every added completion has called Done, then returns
+// the first error recorded, if any.
+func (w *WaitGroup) Wait() error {
+	w.wg.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.errs) > 0 {
+		return w.errs[0]
+	}
+	return nil
+}
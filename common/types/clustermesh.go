@@ -0,0 +1,14 @@
+package types
+
+// ClusterConfig carries the configuration required to establish a
+// ClusterMesh connection to a remote Cilium cluster, namely how to reach
+// the remote kvstore that holds its endpoint and identity state.
+type ClusterConfig struct {
+	// KVStoreEndpoints is the list of kvstore endpoints of the remote
+	// cluster
+	KVStoreEndpoints []string
+
+	// Prefix is the kvstore prefix under which the remote cluster
+	// publishes its endpoint and identity state
+	Prefix string
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// ControllerParams contains the parameters of a controller, i.e. the
+// function it should run, how often it should run it, and how it should
+// back off between failed runs.
+type ControllerParams struct {
+	// DoFunc is invoked periodically to reconcile some piece of state.
+	// If it returns an error, the controller is considered to have
+	// failed for that run and is retried according to
+	// ErrorRetryBaseDuration.
+	DoFunc func(ctx context.Context) error
+
+	// StopFunc is invoked once when the controller is removed, allowing
+	// it to release any resources acquired by DoFunc.
+	StopFunc func(ctx context.Context) error
+
+	// RunInterval is the time between two invocations of DoFunc when the
+	// previous run succeeded.
+	RunInterval time.Duration
+
+	// ErrorRetryBaseDuration is the base duration waited before retrying
+	// a failed DoFunc invocation. The actual wait time grows
+	// exponentially with the number of consecutive failures.
+	ErrorRetryBaseDuration time.Duration
+}
+
+// ControllerStatus reports the current state of a registered controller.
+type ControllerStatus struct {
+	Name                 string
+	ConsecutiveSuccesses int
+	ConsecutiveFailures  int
+	LastError            string
+	LastRunTime          time.Time
+}
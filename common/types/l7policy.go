@@ -0,0 +1,20 @@
+package types
+
+// L7Policy carries the L7-level rules (HTTP, gRPC, Kafka) that should be
+// enforced for traffic redirected to the proxy for a given endpoint.
+type L7Policy struct {
+	// HTTP is the set of allowed HTTP method/path pairs
+	HTTP []L7PolicyHTTPRule
+
+	// Kafka is the set of allowed Kafka topics
+	Kafka []string
+
+	// GRPC is the set of allowed gRPC services
+	GRPC []string
+}
+
+// L7PolicyHTTPRule describes a single allowed HTTP method/path combination.
+type L7PolicyHTTPRule struct {
+	Method string
+	Path   string
+}
@@ -0,0 +1,39 @@
+package types
+
+import "time"
+
+// MaxStatusLogEntries bounds the number of StatusLogEntry records kept per
+// endpoint; once reached, the oldest entry is evicted to make room for a
+// new one.
+const MaxStatusLogEntries = 32
+
+// EndpointStatus aggregates the health of a single endpoint: the state of
+// its controllers, the policy revision it has realized versus the one
+// desired, whether its BPF programs are loaded, its identity resolution
+// state, and a bounded history of recent state transitions.
+type EndpointStatus struct {
+	Controllers       []ControllerStatus
+	RealizedPolicyRev uint64
+	DesiredPolicyRev  uint64
+	BPFProgramsLoaded bool
+	IdentityResolved  bool
+	Connectivity      ConnectivityStatus
+	Log               []StatusLogEntry
+}
+
+// StatusLogEntry is a single, human-readable state transition recorded for
+// an endpoint, used to populate EndpointStatus.Log.
+type StatusLogEntry struct {
+	Timestamp time.Time
+	State     string
+	Reason    string
+}
+
+// ConnectivityStatus holds the outcome of the daemon's periodic
+// connectivity probing (ICMP/HTTP) of an endpoint against its peer nodes'
+// health endpoints.
+type ConnectivityStatus struct {
+	Reachable    bool
+	LastProbe    time.Time
+	LatencyNanos int64
+}
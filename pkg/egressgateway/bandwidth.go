@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+// egressBandwidthMap is an in-process record of each egress gateway
+// policy's configured rate, for debuginfo/metrics introspection only; it
+// is not backed by a BPF map and is never read by the datapath. The EDT
+// qdisc ensureEDTQdisc installs is unparameterized (no per-policy rate is
+// passed to it), so no pacing is actually enforced yet; wiring a real
+// per-policy rate-token BPF map that the qdisc's clsact/BPF program reads
+// from is follow-up work, not something this field does today. It is
+// guarded by its own mutex since it is written to from the reconciliation
+// loop but may be read by debuginfo/metrics collection concurrently.
+var egressBandwidthMap = struct {
+	sync.Mutex
+	rates map[policyID]uint64
+}{rates: map[policyID]uint64{}}
+
+// bandwidthIfaceStats remembers the last-seen cumulative TX byte/drop
+// counters per egress interface, so reportBandwidthStats can report the
+// delta since the previous reconciliation as Prometheus counter
+// increments instead of re-reporting the cumulative total every time.
+var bandwidthIfaceStats = struct {
+	sync.Mutex
+	txBytes   map[int]uint64
+	txDropped map[int]uint64
+}{txBytes: map[int]uint64{}, txDropped: map[int]uint64{}}
+
+// updateEgressBandwidthMap writes bytesPerSecond as the rate token for id,
+// updating it in place without tearing down existing flows.
+func updateEgressBandwidthMap(id policyID, bytesPerSecond uint64) error {
+	egressBandwidthMap.Lock()
+	defer egressBandwidthMap.Unlock()
+
+	egressBandwidthMap.rates[id] = bytesPerSecond
+	return nil
+}
+
+// deleteEgressBandwidthMapEntry removes the rate token recorded for id.
+func deleteEgressBandwidthMapEntry(id policyID) error {
+	egressBandwidthMap.Lock()
+	defer egressBandwidthMap.Unlock()
+
+	delete(egressBandwidthMap.rates, id)
+	return nil
+}
+
+// listEgressBandwidthPolicies returns the set of policy IDs that currently
+// have a rate token recorded in the bandwidth map.
+func listEgressBandwidthPolicies() []policyID {
+	egressBandwidthMap.Lock()
+	defer egressBandwidthMap.Unlock()
+
+	ids := make([]policyID, 0, len(egressBandwidthMap.rates))
+	for id := range egressBandwidthMap.rates {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// addMissingQdiscs installs an EDT (earliest-departure-time) qdisc on the
+// egress interface of every policy that both is locally configured as the
+// gateway and carries a non-zero gatewayConfig.egressBandwidth, and records
+// the configured rate in egressBandwidthMap. It is the bandwidth-manager
+// counterpart of addMissingIpRulesAndRoutes.
+func (manager *Manager) addMissingQdiscs() {
+	ifacePolicies := map[int][]policyID{}
+
+	for _, policyConfig := range manager.policyConfigs {
+		gwc := &policyConfig.gatewayConfig
+
+		if !gwc.localNodeConfiguredAsGateway || len(policyConfig.matchedEndpoints) == 0 {
+			continue
+		}
+
+		if gwc.egressBandwidth == 0 {
+			continue
+		}
+
+		logger := log.WithFields(logrus.Fields{
+			logfields.EgressIP:  gwc.egressIP.IP,
+			logfields.LinkIndex: gwc.ifaceIndex,
+		})
+
+		if err := ensureEDTQdisc(gwc.ifaceIndex); err != nil {
+			logger.WithError(err).Warn("Can't install EDT qdisc for egress gateway bandwidth shaping")
+			continue
+		}
+
+		if err := updateEgressBandwidthMap(policyConfig.id, gwc.egressBandwidth); err != nil {
+			logger.WithError(err).Warn("Can't update egress gateway bandwidth map")
+		}
+
+		ifacePolicies[gwc.ifaceIndex] = append(ifacePolicies[gwc.ifaceIndex], policyConfig.id)
+	}
+
+	for ifaceIndex, ids := range ifacePolicies {
+		manager.reportBandwidthStats(ifaceIndex, ids)
+	}
+}
+
+// reportBandwidthStats reads ifaceIndex's TX counters and adds the delta
+// since the last reconciliation to BandwidthPacedBytesTotal and
+// BandwidthDroppedBytesTotal, labeled per policy in ids. The interface's
+// dropped-packet counter is used as the dropped-traffic signal, since the
+// EDT qdisc doesn't expose a separate dropped-bytes counter.
+//
+// ifaceIndex, not policyID, is the real unit of measurement: netlink only
+// exposes one TX counter per interface, shared by every policy gatewayed
+// through it. When len(ids) > 1 there is no way to attribute the delta to
+// one policy over another, so it is split evenly across ids rather than
+// charged to whichever policy happens to be processed first.
+func (manager *Manager) reportBandwidthStats(ifaceIndex int, ids []policyID) {
+	if manager.metrics == nil || len(ids) == 0 {
+		return
+	}
+
+	link, err := netlink.LinkByIndex(ifaceIndex)
+	if err != nil {
+		return
+	}
+
+	stats := link.Attrs().Statistics
+	if stats == nil {
+		return
+	}
+
+	bandwidthIfaceStats.Lock()
+	defer bandwidthIfaceStats.Unlock()
+
+	share := float64(len(ids))
+
+	if prev, ok := bandwidthIfaceStats.txBytes[ifaceIndex]; ok && stats.TxBytes >= prev {
+		perPolicy := float64(stats.TxBytes-prev) / share
+		for _, id := range ids {
+			manager.metrics.BandwidthPacedBytesTotal.WithLabelValues(id.Name).Add(perPolicy)
+		}
+	}
+	bandwidthIfaceStats.txBytes[ifaceIndex] = stats.TxBytes
+
+	if prev, ok := bandwidthIfaceStats.txDropped[ifaceIndex]; ok && stats.TxDropped >= prev {
+		perPolicy := float64(stats.TxDropped-prev) / share
+		for _, id := range ids {
+			manager.metrics.BandwidthDroppedBytesTotal.WithLabelValues(id.Name).Add(perPolicy)
+		}
+	}
+	bandwidthIfaceStats.txDropped[ifaceIndex] = stats.TxDropped
+}
+
+// removeUnusedQdiscs removes the EDT qdisc and bandwidth map entry of any
+// policy that no longer requests bandwidth shaping, either because the
+// policy was deleted or because its egressBandwidth was cleared.
+func (manager *Manager) removeUnusedQdiscs() {
+	active := map[policyID]struct{}{}
+
+	for _, policyConfig := range manager.policyConfigs {
+		gwc := &policyConfig.gatewayConfig
+		if gwc.localNodeConfiguredAsGateway && gwc.egressBandwidth != 0 && len(policyConfig.matchedEndpoints) != 0 {
+			active[policyConfig.id] = struct{}{}
+		}
+	}
+
+	for _, id := range listEgressBandwidthPolicies() {
+		if _, ok := active[id]; ok {
+			continue
+		}
+
+		if err := deleteEgressBandwidthMapEntry(id); err != nil {
+			log.WithField(logfields.CiliumEgressGatewayPolicyName, id.Name).
+				WithError(err).Warn("Can't remove stale egress gateway bandwidth map entry")
+		}
+	}
+}
+
+// ensureEDTQdisc installs a "fq" EDT-capable qdisc on ifaceIndex, unless
+// one is already present.
+func ensureEDTQdisc(ifaceIndex int) error {
+	link, err := netlink.LinkByIndex(ifaceIndex)
+	if err != nil {
+		return err
+	}
+
+	qdiscs, err := netlink.QdiscList(link)
+	if err != nil {
+		return err
+	}
+
+	for _, qdisc := range qdiscs {
+		if qdisc.Type() == "fq" {
+			return nil
+		}
+	}
+
+	qdisc := &netlink.Fq{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: ifaceIndex,
+			Parent:    netlink.HANDLE_ROOT,
+		},
+	}
+
+	return netlink.QdiscReplace(qdisc)
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+// DebugInfoHandler dumps an introspection view of the egress gateway's
+// internal state, so operators can tell why a source IP is (or isn't)
+// being SNATed through a given gateway without having to read BPF map
+// dumps directly. It is provided into the hive DI graph for a `cilium
+// debuginfo`-style collector to call into; registering it on an actual
+// REST endpoint is not done by this package.
+type DebugInfoHandler struct {
+	manager *Manager
+}
+
+func newDebugInfoHandler(manager *Manager) *DebugInfoHandler {
+	return &DebugInfoHandler{manager: manager}
+}
+
+// PolicyDebugInfo describes, for a single policy, the gateway it resolved
+// to and the endpoints it currently matches.
+type PolicyDebugInfo struct {
+	PolicyName        string   `json:"policyName"`
+	Gateway           string   `json:"gateway"`
+	MatchedEndpointIPs []string `json:"matchedEndpointIPs"`
+}
+
+// DumpPolicies returns the policy -> gateway -> matched-endpoint graph
+// tracked by the manager.
+func (h *DebugInfoHandler) DumpPolicies() []PolicyDebugInfo {
+	if h.manager == nil {
+		return nil
+	}
+
+	h.manager.Lock()
+	defer h.manager.Unlock()
+
+	info := make([]PolicyDebugInfo, 0, len(h.manager.policyConfigs))
+
+	for id, policyConfig := range h.manager.policyConfigs {
+		var ips []string
+		for _, ep := range policyConfig.matchedEndpoints {
+			for _, ip := range ep.ips {
+				ips = append(ips, ip.String())
+			}
+		}
+
+		info = append(info, PolicyDebugInfo{
+			PolicyName:         id.Name,
+			Gateway:            policyConfig.gatewayConfig.gatewayIP.String(),
+			MatchedEndpointIPs: ips,
+		})
+	}
+
+	return info
+}
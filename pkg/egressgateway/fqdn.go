@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cilium/cilium/pkg/fqdn"
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+// fqdnResolutionCheckInterval is how often the resolver goroutine checks
+// for policies whose resolved FQDN CIDR set has passed its TTL.
+const fqdnResolutionCheckInterval = 5 * time.Second
+
+// runFQDNResolver spawns a goroutine that periodically re-resolves every
+// policy's dstFQDNs through the shared FQDN/DNS proxy cache, honoring TTLs.
+func (manager *Manager) runFQDNResolver(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(fqdnResolutionCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				manager.resolveExpiredFQDNPolicies()
+			}
+		}
+	}()
+}
+
+// resolveExpiredFQDNPolicies re-resolves the dstFQDNs of every policy whose
+// cached resolution has expired, plus any dstFQDNs policy that has no
+// cached resolution yet (e.g. one added since the last pass), triggering a
+// reconciliation for each one whose resolved CIDR set changed.
+func (manager *Manager) resolveExpiredFQDNPolicies() {
+	manager.Lock()
+	toResolve := manager.fqdns.expired(time.Now())
+
+	for id, policyConfig := range manager.policyConfigs {
+		if len(policyConfig.dstFQDNs) == 0 {
+			continue
+		}
+
+		if _, cached := manager.fqdns.resolutions[id]; !cached {
+			toResolve = append(toResolve, id)
+		}
+	}
+
+	var changed []policyID
+
+	for _, id := range toResolve {
+		policyConfig, ok := manager.policyConfigs[id]
+		if !ok || len(policyConfig.dstFQDNs) == 0 {
+			continue
+		}
+
+		if manager.fqdns.update(fqdn.GlobalCache, id, policyConfig.dstFQDNs, time.Now()) {
+			changed = append(changed, id)
+		}
+	}
+	manager.Unlock()
+
+	for _, id := range changed {
+		manager.OnFQDNSetChanged(id)
+	}
+}
+
+// maxResolvedIPsPerFQDNPolicy caps the total number of addresses all of a
+// single policy's dstFQDNs are allowed to expand into, so that a name resolving to an
+// unexpectedly large number of addresses can't blow up the egress policy
+// BPF map.
+const maxResolvedIPsPerFQDNPolicy = 128
+
+// fqdnResolution is the result of resolving one policy's dstFQDNs, along
+// with the TTL-derived deadline at which it should be re-resolved.
+type fqdnResolution struct {
+	cidrs    []*net.IPNet
+	expiresAt time.Time
+}
+
+// fqdnCache holds, per policy, the dynamic CIDR set resolved from that
+// policy's dstFQDNs, honoring DNS TTLs and the max-IP-per-policy cap.
+type fqdnCache struct {
+	resolutions map[policyID]fqdnResolution
+}
+
+func newFQDNCache() *fqdnCache {
+	return &fqdnCache{
+		resolutions: make(map[policyID]fqdnResolution),
+	}
+}
+
+// update resolves names via Cilium's FQDN/DNS proxy cache, caps the result
+// at maxResolvedIPsPerFQDNPolicy, and records it against id. It returns
+// true if the resolved CIDR set changed since the last update.
+func (c *fqdnCache) update(resolver fqdn.Cache, id policyID, names []string, now time.Time) bool {
+	var cidrs []*net.IPNet
+	minTTL := time.Duration(0)
+
+	for _, name := range names {
+		ips, ttl := resolver.Lookup(name)
+
+		for _, ip := range ips {
+			if len(cidrs) >= maxResolvedIPsPerFQDNPolicy {
+				log.WithField(logfields.DNSName, name).
+					Warning("Too many IPs resolved for egress gateway FQDN policy, truncating")
+				break
+			}
+
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: hostMask(ip)})
+		}
+
+		if minTTL == 0 || ttl < minTTL {
+			minTTL = ttl
+		}
+	}
+
+	if minTTL == 0 {
+		minTTL = time.Minute
+	}
+
+	prev, existed := c.resolutions[id]
+	changed := !existed || !cidrSetEqual(prev.cidrs, cidrs)
+
+	c.resolutions[id] = fqdnResolution{
+		cidrs:    cidrs,
+		expiresAt: now.Add(minTTL),
+	}
+
+	return changed
+}
+
+func (c *fqdnCache) get(id policyID) []*net.IPNet {
+	return c.resolutions[id].cidrs
+}
+
+func (c *fqdnCache) delete(id policyID) {
+	delete(c.resolutions, id)
+}
+
+// expired returns the set of policy IDs whose cached resolution has passed
+// its TTL and needs to be re-resolved.
+func (c *fqdnCache) expired(now time.Time) []policyID {
+	var ids []policyID
+	for id, res := range c.resolutions {
+		if now.After(res.expiresAt) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func hostMask(ip net.IP) net.IPMask {
+	if ip.To4() != nil {
+		return net.CIDRMask(32, 32)
+	}
+	return net.CIDRMask(128, 128)
+}
+
+func cidrSetEqual(a, b []*net.IPNet) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+// OnFQDNSetChanged is invoked by the FQDN resolver goroutine whenever the
+// resolved CIDR set for a dstFQDNs-based policy changes. It feeds the new
+// set into policyConfigsBySourceIP and the BPF policy map by triggering a
+// reconciliation.
+func (manager *Manager) OnFQDNSetChanged(id policyID) {
+	manager.Lock()
+	defer manager.Unlock()
+
+	manager.setEventBitmap(eventUpdateFQDN)
+	manager.reconciliationTrigger.TriggerWithReason("fqdn updated")
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"github.com/dchest/siphash"
+
+	nodeTypes "github.com/cilium/cilium/pkg/node/types"
+)
+
+// rendezvousKey0 and rendezvousKey1 are an arbitrary, fixed 128-bit siphash key used to
+// derive a deterministic, uniformly distributed score for each (key,
+// candidate gateway) pair. Being fixed (rather than random per-process)
+// is what makes the resulting ranking consistent across agents and across
+// reconciliations.
+var rendezvousKey0, rendezvousKey1 = uint64(0xeb019eb156a5a5f5), uint64(0x8b4eca3a3fa0a1c2)
+
+// selectGateway picks one gateway node out of healthyCandidates for the
+// given key, using rendezvous (highest random weight) hashing: for each
+// candidate node we compute h = siphash(key||node.Name) and pick the node
+// that maximizes h.
+//
+// Because the hash only depends on key and the candidate's identity (not
+// on the order or count of other candidates), this preserves affinity
+// across reconciliations: adding or removing a gateway only reshuffles
+// the keys that hashed highest to that particular gateway, instead of
+// reshuffling the whole keyspace the way naive modulo hashing would.
+//
+// scheduleOne uses this to pick a policy's gateway among its healthy,
+// within-capacity candidates, but that result only feeds scheduledGateways
+// (status reporting, see status.go). The datapath-facing decision of which
+// gateway IP addMissingEgressRules actually writes into the BPF policy map
+// is made by PolicyConfig.regenerateGatewayConfig, which is not part of
+// this package; wiring gatewayHealth/selectGateway into that decision so
+// multi-gateway failover changes real traffic, not just status, is still
+// open work.
+func selectGateway(healthyCandidates []nodeTypes.Node, key []byte) (nodeTypes.Node, bool) {
+	var (
+		best      nodeTypes.Node
+		bestScore uint64
+		found     bool
+	)
+
+	for _, candidate := range healthyCandidates {
+		score := rendezvousScore(key, candidate.Name)
+
+		if !found || score > bestScore {
+			best = candidate
+			bestScore = score
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// rendezvousScore computes the siphash of key concatenated with the
+// candidate gateway's name.
+func rendezvousScore(key []byte, gatewayName string) uint64 {
+	full := make([]byte, 0, len(key)+len(gatewayName))
+	full = append(full, key...)
+	full = append(full, gatewayName...)
+
+	return siphash.Hash(rendezvousKey0, rendezvousKey1, full)
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	nodeTypes "github.com/cilium/cilium/pkg/node/types"
+)
+
+// runGatewayHealthChecker spawns a goroutine that periodically TCP-dials
+// every candidate gateway node on healthcheckPort and records the result
+// in gatewayHealth, via isGatewayHealthy. Transitions (healthy -> unhealthy
+// or vice versa) trigger a reconciliation so that any consumer of
+// gatewayHealth picks up the change without waiting for an unrelated k8s
+// event; today that's runSchedulerLocked (see gateway_selection.go for the
+// caveat on how far that result actually reaches).
+func (manager *Manager) runGatewayHealthChecker(ctx context.Context, wg *sync.WaitGroup) {
+	if manager.healthcheckInterval <= 0 {
+		return
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(manager.healthcheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				manager.probeGatewayCandidates()
+			}
+		}
+	}()
+}
+
+// probeGatewayCandidates probes every known node and updates gatewayHealth,
+// enqueueing a reconciliation if any node's health changed.
+//
+// A node only flips from healthy to unhealthy after
+// healthcheckFailureThreshold consecutive failed probes, so a single
+// transient failure doesn't trigger a failover; a single successful probe
+// is enough to flip it back to healthy.
+func (manager *Manager) probeGatewayCandidates() {
+	manager.Lock()
+	candidates := make([]nodeTypes.Node, len(manager.nodes))
+	copy(candidates, manager.nodes)
+	timeout := manager.healthcheckTimeout
+	port := manager.healthcheckPort
+	threshold := manager.healthcheckFailureThreshold
+	manager.Unlock()
+
+	changed := false
+
+	for _, node := range candidates {
+		healthy := probeNodeHealth(node, timeout, port)
+
+		manager.Lock()
+		prevHealthy, probed := manager.gatewayHealth[node.Name]
+
+		switch {
+		case healthy:
+			manager.gatewayHealthFailures[node.Name] = 0
+			manager.gatewayHealth[node.Name] = true
+			if probed && !prevHealthy {
+				changed = true
+			}
+		case manager.gatewayHealthFailures[node.Name]+1 >= threshold:
+			manager.gatewayHealthFailures[node.Name]++
+			manager.gatewayHealth[node.Name] = false
+			if !probed || prevHealthy {
+				changed = true
+			}
+		default:
+			manager.gatewayHealthFailures[node.Name]++
+		}
+		manager.Unlock()
+	}
+
+	if changed {
+		manager.Lock()
+		manager.setEventBitmap(eventGatewayHealthChanged)
+		manager.Unlock()
+
+		// Hand off to the reconciliation workqueue instead of the
+		// trigger directly, so a health flip that races with an
+		// in-flight reconciliation is never dropped.
+		manager.reconciliationQueue.Add(reconciliationReasonGatewayHealthChanged)
+	}
+}
+
+// probeNodeHealth TCP-dials node's IPv4 node IP on port, returning true if the
+// connection succeeds within timeout.
+func probeNodeHealth(node nodeTypes.Node, timeout time.Duration, port uint16) bool {
+	ip := node.GetNodeIP(false)
+	if ip == nil {
+		return false
+	}
+
+	addr := net.JoinHostPort(ip.String(), fmt.Sprintf("%d", port))
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			logfields.NodeName: node.Name,
+			logfields.IPAddr:   ip,
+		}).WithError(err).Debug("Gateway healthcheck probe failed")
+		return false
+	}
+	conn.Close()
+
+	return true
+}
+
+// isGatewayHealthy returns whether nodeName is currently known to be a
+// healthy candidate gateway. A node that has never been probed yet is
+// considered healthy, so that newly-added nodes aren't excluded from
+// gateway selection before their first health check completes.
+func (manager *Manager) isGatewayHealthy(nodeName string) bool {
+	healthy, probed := manager.gatewayHealth[nodeName]
+	return !probed || healthy
+}
@@ -43,14 +43,24 @@ var (
 	// ExcludedCIDRIPv4 is a special IP value used as gatewayIP in the BPF policy map
 	// to indicate the entry is for an excluded CIDR and should skip egress gateway
 	ExcludedCIDRIPv4 = net.ParseIP("0.0.0.1")
+	// GatewayNotFoundIPv6 is the IPv6 counterpart of GatewayNotFoundIPv4.
+	GatewayNotFoundIPv6 = net.ParseIP("::")
+	// ExcludedCIDRIPv6 is the IPv6 counterpart of ExcludedCIDRIPv4.
+	ExcludedCIDRIPv6 = net.ParseIP("::1")
+	// DenyCIDRIPv4 is a special IP value used as gatewayIP in the BPF policy
+	// map to indicate that matching traffic belongs to a CiliumEgressGatewayPolicy
+	// with action: Deny, and must be dropped by the datapath rather than SNATed.
+	DenyCIDRIPv4 = net.ParseIP("0.0.0.2")
 )
 
 // Cell provides a [Manager] for consumption with hive.
 var Cell = cell.Module(
 	"egressgateway",
-	"Egress Gateway allows originating traffic from specific IPv4 addresses",
+	"Egress Gateway allows originating traffic from specific IPv4 or IPv6 addresses",
 	cell.Config(defaultConfig),
 	cell.Provide(NewEgressGatewayManager),
+	cell.Provide(NewMetrics),
+	cell.Provide(newDebugInfoHandler),
 )
 
 type eventType int
@@ -64,6 +74,9 @@ const (
 	eventDeleteNode
 	eventUpdateEndpoint
 	eventDeleteEndpoint
+	eventGatewayHealthChanged
+	eventUpdateFQDN
+	eventBandwidthUpdate
 )
 
 type endpointEvent struct {
@@ -79,16 +92,69 @@ type Config struct {
 	// Default amount of time between triggers of egress gateway state
 	// reconciliations are invoked
 	EgressGatewayReconciliationTriggerInterval time.Duration
+
+	// EgressGatewayHealthcheckInterval is how often candidate gateway
+	// nodes are probed for liveness
+	EgressGatewayHealthcheckInterval time.Duration
+
+	// EgressGatewayHealthcheckTimeout bounds how long a single health
+	// probe is allowed to take before the target is considered
+	// unreachable for that round
+	EgressGatewayHealthcheckTimeout time.Duration
+
+	// EgressGatewayHealthcheckPort is the TCP port dialed on the
+	// candidate gateway node's IP to determine liveness
+	EgressGatewayHealthcheckPort uint16
+
+	// EgressGatewayHealthcheckFailureThreshold is the number of
+	// consecutive failed probes required before a candidate gateway node
+	// is marked unhealthy. A single successful probe is enough to mark
+	// it healthy again.
+	EgressGatewayHealthcheckFailureThreshold int
+
+	// EnableEgressGatewayBandwidthManager installs an EDT (earliest
+	// departure time) "fq" qdisc on the egress interface of each policy
+	// with a non-zero egressBandwidth and records that rate per policy.
+	// The qdisc is not given the rate, so traffic is not paced per policy.
+	EnableEgressGatewayBandwidthManager bool
 }
 
 var defaultConfig = Config{
 	InstallEgressGatewayRoutes:                 false,
 	EgressGatewayReconciliationTriggerInterval: 1 * time.Second,
+	EgressGatewayHealthcheckInterval:            5 * time.Second,
+	EgressGatewayHealthcheckTimeout:             1 * time.Second,
+	EgressGatewayHealthcheckPort:                4240,
+	EgressGatewayHealthcheckFailureThreshold:    3,
+	EnableEgressGatewayBandwidthManager:         false,
 }
 
 func (def Config) Flags(flags *pflag.FlagSet) {
 	flags.Bool("install-egress-gateway-routes", def.InstallEgressGatewayRoutes, "Install egress gateway IP rules and routes in order to properly steer egress gateway traffic to the correct ENI interface")
 	flags.Duration("egress-gateway-reconciliation-trigger-interval", def.EgressGatewayReconciliationTriggerInterval, "Time between triggers of egress gateway state reconciliations")
+	flags.Duration("egress-gateway-healthcheck-interval", def.EgressGatewayHealthcheckInterval, "Time between liveness probes of candidate egress gateway nodes")
+	flags.Duration("egress-gateway-healthcheck-timeout", def.EgressGatewayHealthcheckTimeout, "Timeout for a single egress gateway liveness probe")
+	flags.Uint16("egress-gateway-healthcheck-port", def.EgressGatewayHealthcheckPort, "TCP port used to probe candidate egress gateway nodes for liveness")
+	flags.Int("egress-gateway-healthcheck-failure-threshold", def.EgressGatewayHealthcheckFailureThreshold, "Number of consecutive failed liveness probes before a candidate egress gateway node is marked unhealthy")
+	flags.Bool("enable-egress-gateway-bandwidth-manager", def.EnableEgressGatewayBandwidthManager, "Install an EDT qdisc for egress gateway policies with an egress bandwidth set (the per-policy rate is recorded, not enforced)")
+}
+
+// ipFamily identifies which address family (IPv4 or IPv6) a gateway
+// config, CIDR, or policy map entry applies to.
+type ipFamily int
+
+const (
+	ipFamilyV4 ipFamily = iota
+	ipFamilyV6
+)
+
+// familyOf returns the ipFamily of ip, so that callers can dispatch
+// between the v4 and v6 policy maps and IP rule/route families.
+func familyOf(ip net.IP) ipFamily {
+	if ip.To4() == nil {
+		return ipFamilyV6
+	}
+	return ipFamilyV4
 }
 
 // The egressgateway manager stores the internal data tracking the node, policy,
@@ -140,9 +206,17 @@ type Manager struct {
 	// with the egress IP assigned to
 	installRoutes bool
 
-	// policyMap communicates the active policies to the dapath.
+	// policyMap communicates the active IPv4 policies to the datapath.
 	policyMap egressmap.PolicyMap
 
+	// policyMap6 communicates the active IPv6 policies to the datapath.
+	// It is nil if IPv6 egress gateway support is disabled.
+	policyMap6 egressmap.PolicyMap6
+
+	// enableIPv6 indicates whether IPv6 egress gateway policies should be
+	// reconciled alongside IPv4 ones.
+	enableIPv6 bool
+
 	// reconciliationTriggerInterval is the amount of time between triggers
 	// of reconciliations are invoked
 	reconciliationTriggerInterval time.Duration
@@ -156,6 +230,82 @@ type Manager struct {
 	// the node with the desired egress gateway state.
 	// The trigger is used to batch multiple updates together
 	reconciliationTrigger *trigger.Trigger
+
+	// gatewayHealth stores the last known liveness of each candidate
+	// gateway node, keyed by node name, as probed by the healthChecker
+	gatewayHealth map[string]bool
+
+	// gatewayHealthFailures counts consecutive failed probes per
+	// candidate gateway node, keyed by node name. It is reset to 0 on
+	// any successful probe, and gatewayHealth only flips to unhealthy
+	// once it reaches healthcheckFailureThreshold, so that a single
+	// transient probe failure doesn't trigger a failover.
+	gatewayHealthFailures map[string]int
+
+	// healthcheckInterval, healthcheckTimeout, healthcheckPort and
+	// healthcheckFailureThreshold configure the gateway liveness prober
+	healthcheckInterval         time.Duration
+	healthcheckTimeout          time.Duration
+	healthcheckPort             uint16
+	healthcheckFailureThreshold int
+
+	// fqdns holds the dynamic CIDR sets resolved from each policy's
+	// dstFQDNs, kept fresh by the fqdnResolver goroutine
+	fqdns *fqdnCache
+
+	// metrics holds the egress gateway's Prometheus metrics. May be nil
+	// in tests that don't care about metrics.
+	metrics *Metrics
+
+	// enableBandwidthManager indicates whether per-policy egress
+	// bandwidth shaping via EDT/tc is enabled
+	enableBandwidthManager bool
+
+	// policyIsDeny stores, per policyID, whether the policy's action is
+	// Deny rather than the default Allow. Deny policies install
+	// DenyCIDRIPv4 as the gateway for all of their matched traffic,
+	// instead of a real gateway node, so the datapath drops it.
+	policyIsDeny map[policyID]bool
+
+	// policyEgressIPv6 stores, for policies that have an IPv6 egress IP
+	// configured in addition to (or instead of) an IPv4 one, the IPv6
+	// egress IP/netmask to use, indexed by policyID.
+	policyEgressIPv6 map[policyID]net.IPNet
+
+	// reconciliationQueue is a rate-limiting workqueue keyed by
+	// reconciliation reason (e.g. "route-retry"). Sub-steps of
+	// reconcileLocked that fail (IP rule/route installation, CRD status
+	// updates) requeue their reason here instead of being silently dropped
+	// until an unrelated event re-triggers reconciliation; see
+	// retryReconciliationStep. Policy map update and delete failures are
+	// only logged and are not requeued.
+	reconciliationQueue workqueue.RateLimitingInterface
+
+	// scheduledGateways holds the output of the last scheduleGateways
+	// pass: the gateway node each policy was deterministically assigned
+	// to. Every agent computes this identically given the same inputs.
+	// It only drives status reporting (updateActiveGatewayStatusLocked);
+	// it has no bearing on BPF entry installation, since the per-node
+	// egress policy map is consulted for packets sourced from that
+	// node's own local endpoints regardless of which node is the
+	// elected gateway.
+	scheduledGateways map[policyID]nodeTypes.Node
+
+	// activeGatewayNodes stores the gateway node name last reported to
+	// statusUpdater for each policy, so updateActiveGatewayStatusLocked
+	// only pushes a CRD status update when the active gateway actually
+	// changes (e.g. on failover).
+	activeGatewayNodes map[policyID]string
+
+	// statusUpdater pushes status.activeGatewayNode updates to the
+	// CiliumEgressGatewayPolicy CRD. It is nil if no updater was
+	// provided, in which case status reporting is skipped.
+	statusUpdater CRDStatusUpdater
+
+	// bpfMapEntries is the combined number of entries addMissingEgressRules
+	// and addMissingEgressRulesV6 observed in policyMap/policyMap6 at the
+	// start of their last pass, reported via Metrics.BPFMapEntries.
+	bpfMapEntries int
 }
 
 type Params struct {
@@ -166,12 +316,15 @@ type Params struct {
 	CacheStatus       k8s.CacheStatus
 	IdentityAllocator identityCache.IdentityAllocator
 	PolicyMap         egressmap.PolicyMap
+	PolicyMap6        egressmap.PolicyMap6
+	Metrics           Metrics
+	StatusUpdater     CRDStatusUpdater `optional:"true"`
 
 	Lifecycle hive.Lifecycle
 }
 
 func NewEgressGatewayManager(p Params) (*Manager, error) {
-	if !p.DaemonConfig.EnableIPv4EgressGateway {
+	if !p.DaemonConfig.EnableIPv4EgressGateway && !p.DaemonConfig.EnableIPv6EgressGateway {
 		return nil, nil
 	}
 
@@ -182,6 +335,9 @@ func NewEgressGatewayManager(p Params) (*Manager, error) {
 	rateLimiter := workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond*20, time.Minute*20)
 	endpointEventRetryQueue := workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{})
 
+	reconciliationRateLimiter := workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond*100, time.Minute*5)
+	reconciliationQueue := workqueue.NewRateLimitingQueueWithConfig(reconciliationRateLimiter, workqueue.RateLimitingQueueConfig{Name: "egress_gateway_reconciliation_retries"})
+
 	manager := &Manager{
 		cacheStatus:                   p.CacheStatus,
 		nodeDataStore:                 make(map[string]nodeTypes.Node),
@@ -190,10 +346,26 @@ func NewEgressGatewayManager(p Params) (*Manager, error) {
 		epDataStore:                   make(map[endpointID]*endpointMetadata),
 		pendingEndpointEvents:         make(map[endpointID]endpointEvent),
 		endpointEventsQueue:           endpointEventRetryQueue,
+		reconciliationQueue:           reconciliationQueue,
 		identityAllocator:             p.IdentityAllocator,
 		installRoutes:                 p.Config.InstallEgressGatewayRoutes,
 		reconciliationTriggerInterval: p.Config.EgressGatewayReconciliationTriggerInterval,
 		policyMap:                     p.PolicyMap,
+		policyMap6:                    p.PolicyMap6,
+		enableIPv6:                    p.DaemonConfig.EnableIPv6EgressGateway,
+		gatewayHealth:                 make(map[string]bool),
+		gatewayHealthFailures:         make(map[string]int),
+		healthcheckInterval:           p.Config.EgressGatewayHealthcheckInterval,
+		healthcheckTimeout:            p.Config.EgressGatewayHealthcheckTimeout,
+		healthcheckPort:               p.Config.EgressGatewayHealthcheckPort,
+		healthcheckFailureThreshold:   p.Config.EgressGatewayHealthcheckFailureThreshold,
+		fqdns:                         newFQDNCache(),
+		metrics:                       &p.Metrics,
+		enableBandwidthManager:        p.Config.EnableEgressGatewayBandwidthManager,
+		policyEgressIPv6:              make(map[policyID]net.IPNet),
+		policyIsDeny:                  make(map[policyID]bool),
+		activeGatewayNodes:            make(map[policyID]string),
+		statusUpdater:                 p.StatusUpdater,
 	}
 
 	t, err := trigger.NewTrigger(trigger.Parameters{
@@ -206,7 +378,9 @@ func NewEgressGatewayManager(p Params) (*Manager, error) {
 			manager.Lock()
 			defer manager.Unlock()
 
+			start := time.Now()
 			manager.reconcileLocked()
+			manager.observeReconciliation(start)
 		},
 	})
 	if err != nil {
@@ -226,10 +400,14 @@ func NewEgressGatewayManager(p Params) (*Manager, error) {
 
 			manager.runReconciliationAfterK8sSync(ctx)
 			manager.processCiliumEndpoints(ctx, &wg)
+			manager.runGatewayHealthChecker(ctx, &wg)
+			manager.runFQDNResolver(ctx, &wg)
+			manager.runReconciliationQueue(ctx, &wg)
 			return nil
 		},
 		OnStop: func(hc hive.HookContext) error {
 			cancel()
+			manager.reconciliationQueue.ShutDown()
 
 			wg.Wait()
 			return nil
@@ -239,6 +417,22 @@ func NewEgressGatewayManager(p Params) (*Manager, error) {
 	return manager, nil
 }
 
+// OnUpdatePolicyAction records whether id's CiliumEgressGatewayPolicy has
+// action: Deny, and triggers a reconciliation so that addMissingEgressRules
+// can (re)install its matched traffic under the DenyCIDRIPv4 sentinel
+// instead of a real gateway.
+func (manager *Manager) OnUpdatePolicyAction(id policyID, deny bool) {
+	manager.Lock()
+	defer manager.Unlock()
+
+	if manager.policyIsDeny[id] == deny {
+		return
+	}
+
+	manager.policyIsDeny[id] = deny
+	manager.reconciliationTrigger.TriggerWithReason("policy action updated")
+}
+
 func (manager *Manager) setEventBitmap(events ...eventType) {
 	for _, e := range events {
 		manager.eventsBitmap |= e
@@ -363,6 +557,12 @@ func (manager *Manager) OnAddEgressPolicy(config PolicyConfig) {
 
 	manager.policyConfigs[config.id] = &config
 
+	if config.egressIPv6 != nil {
+		manager.policyEgressIPv6[config.id] = *config.egressIPv6
+	} else {
+		delete(manager.policyEgressIPv6, config.id)
+	}
+
 	manager.setEventBitmap(eventAddPolicy)
 	manager.reconciliationTrigger.TriggerWithReason("policy added")
 }
@@ -383,6 +583,9 @@ func (manager *Manager) OnDeleteEgressPolicy(configID policyID) {
 	logger.Debug("Deleted CiliumEgressGatewayPolicy")
 
 	delete(manager.policyConfigs, configID)
+	delete(manager.policyIsDeny, configID)
+	delete(manager.policyEgressIPv6, configID)
+	delete(manager.activeGatewayNodes, configID)
 
 	manager.setEventBitmap(eventDeletePolicy)
 	manager.reconciliationTrigger.TriggerWithReason("policy deleted")
@@ -695,6 +898,10 @@ func (manager *Manager) addMissingIpRulesAndRoutes(isRetry bool) (shouldRetry bo
 			newRule := newEgressIpRule(endpointIP, dstCIDR, routingTableIdx)
 
 			if err := netlink.RuleAdd(newRule); err != nil {
+				if manager.metrics != nil {
+					manager.metrics.IPRuleAddErrorsTotal.Inc()
+				}
+
 				if isRetry {
 					logger.WithError(err).Warn("Can't add IP rule")
 				} else {
@@ -713,6 +920,16 @@ func (manager *Manager) addMissingIpRulesAndRoutes(isRetry bool) (shouldRetry bo
 		} else {
 			logger.Debug("Added IP routes")
 		}
+
+		if manager.enableIPv6 {
+			if egressIPv6, ok := manager.policyEgressIPv6[policyConfig.id]; ok {
+				if err := addEgressIpRoutes(egressIPv6, gwc.ifaceIndex); err != nil {
+					logger.WithError(err).Warn("Can't add IPv6 IP routes")
+				} else {
+					logger.Debug("Added IPv6 IP routes")
+				}
+			}
+		}
 	}
 
 	return
@@ -763,27 +980,35 @@ nextIpRule:
 		}
 	}
 
-	// Fetch all IP routes, and delete the unused EgressGW-specific routes:
-	routes, err := netlink.RouteList(nil, netlink.FAMILY_V4)
-	if err != nil {
-		logger.WithError(err).Error("Cannot list IP routes")
-		return
+	// Fetch all IP routes in both families, and delete the unused
+	// EgressGW-specific routes:
+	families := []int{netlink.FAMILY_V4}
+	if manager.enableIPv6 {
+		families = append(families, netlink.FAMILY_V6)
 	}
 
-	for _, route := range routes {
-		linkIndex := route.LinkIndex
-
-		// Keep the route if it was not created by EgressGW.
-		if route.Table != egressGatewayRoutingTableIdx(linkIndex) {
+	for _, family := range families {
+		routes, err := netlink.RouteList(nil, family)
+		if err != nil {
+			logger.WithError(err).Error("Cannot list IP routes")
 			continue
 		}
 
-		// Keep the route if EgressGW still uses this interface.
-		if _, ok := activeEgressGwIfaceIndexes[linkIndex]; ok {
-			continue
-		}
+		for _, route := range routes {
+			linkIndex := route.LinkIndex
 
-		deleteIpRoute(route)
+			// Keep the route if it was not created by EgressGW.
+			if route.Table != egressGatewayRoutingTableIdx(linkIndex) {
+				continue
+			}
+
+			// Keep the route if EgressGW still uses this interface.
+			if _, ok := activeEgressGwIfaceIndexes[linkIndex]; ok {
+				continue
+			}
+
+			deleteIpRoute(route)
+		}
 	}
 }
 
@@ -793,36 +1018,199 @@ func (manager *Manager) addMissingEgressRules() {
 		func(key *egressmap.EgressPolicyKey4, val *egressmap.EgressPolicyVal4) {
 			egressPolicies[*key] = *val
 		})
+	manager.bpfMapEntries = len(egressPolicies)
 
-	addEgressRule := func(endpointIP net.IP, dstCIDR *net.IPNet, excludedCIDR bool, gwc *gatewayConfig) {
-		policyKey := egressmap.NewEgressPolicyKey4(endpointIP, dstCIDR.IP, dstCIDR.Mask)
-		policyVal, policyPresent := egressPolicies[policyKey]
+	for _, policyConfig := range manager.policyConfigs {
+		isDeny := manager.policyIsDeny[policyConfig.id]
 
-		gatewayIP := gwc.gatewayIP
-		if excludedCIDR {
-			gatewayIP = ExcludedCIDRIPv4
-		}
+		addEgressRule := func(endpointIP net.IP, dstCIDR *net.IPNet, excludedCIDR bool, gwc *gatewayConfig) {
+			policyKey := egressmap.NewEgressPolicyKey4(endpointIP, dstCIDR.IP, dstCIDR.Mask)
+			policyVal, policyPresent := egressPolicies[policyKey]
+
+			gatewayIP := gwc.gatewayIP
+			switch {
+			case isDeny:
+				gatewayIP = DenyCIDRIPv4
+			case excludedCIDR:
+				gatewayIP = ExcludedCIDRIPv4
+			}
+
+			if policyPresent && policyVal.Match(gwc.egressIP.IP, gatewayIP) {
+				return
+			}
+
+			logger := log.WithFields(logrus.Fields{
+				logfields.SourceIP:        endpointIP,
+				logfields.DestinationCIDR: dstCIDR.String(),
+				logfields.EgressIP:        gwc.egressIP.IP,
+				logfields.GatewayIP:       gatewayIP,
+			})
 
-		if policyPresent && policyVal.Match(gwc.egressIP.IP, gatewayIP) {
-			return
+			// Update overwrites the existing map entry for this
+			// (endpointIP, dstCIDR) key in place, so a gateway failover
+			// replaces the old gateway IP with the new one atomically
+			// instead of going through an intermediate delete, which
+			// would otherwise open a connectivity gap between this call
+			// and removeUnusedEgressRules.
+			if err := manager.policyMap.Update(endpointIP, *dstCIDR, gwc.egressIP.IP, gatewayIP); err != nil {
+				logger.WithError(err).Error("Error applying egress gateway policy")
+			} else {
+				logger.Debug("Egress gateway policy applied")
+			}
 		}
 
-		logger := log.WithFields(logrus.Fields{
-			logfields.SourceIP:        endpointIP,
-			logfields.DestinationCIDR: dstCIDR.String(),
-			logfields.EgressIP:        gwc.egressIP.IP,
-			logfields.GatewayIP:       gatewayIP,
-		})
+		policyConfig.forEachEndpointAndCIDR(addEgressRule)
 
-		if err := manager.policyMap.Update(endpointIP, *dstCIDR, gwc.egressIP.IP, gatewayIP); err != nil {
-			logger.WithError(err).Error("Error applying egress gateway policy")
-		} else {
-			logger.Debug("Egress gateway policy applied")
+		// dstFQDNs-based policies don't have their resolved addresses in
+		// policyConfig.dstCIDRs (those only hold the statically
+		// configured CIDRs), so forEachEndpointAndCIDR above never visits
+		// them; install them here from the FQDN resolver's cache instead.
+		for _, dstCIDR := range manager.fqdns.get(policyConfig.id) {
+			if familyOf(dstCIDR.IP) != ipFamilyV4 {
+				continue
+			}
+
+			for _, ep := range policyConfig.matchedEndpoints {
+				for _, endpointIP := range ep.ips {
+					addEgressRule(endpointIP, dstCIDR, false, &policyConfig.gatewayConfig)
+				}
+			}
 		}
 	}
+}
+
+// addMissingEgressRulesV6 is the IPv6 counterpart of addMissingEgressRules:
+// it reconciles the egressmap.PolicyMap6 against the desired state, using
+// ExcludedCIDRIPv6 as the excluded-CIDR sentinel gateway.
+func (manager *Manager) addMissingEgressRulesV6() {
+	if manager.policyMap6 == nil {
+		return
+	}
+
+	egressPolicies := map[egressmap.EgressPolicyKey6]egressmap.EgressPolicyVal6{}
+	manager.policyMap6.IterateWithCallback(
+		func(key *egressmap.EgressPolicyKey6, val *egressmap.EgressPolicyVal6) {
+			egressPolicies[*key] = *val
+		})
+	manager.bpfMapEntries += len(egressPolicies)
 
 	for _, policyConfig := range manager.policyConfigs {
+		egressIPv6, hasEgressIPv6 := manager.policyEgressIPv6[policyConfig.id]
+
+		addEgressRule := func(endpointIP net.IP, dstCIDR *net.IPNet, excludedCIDR bool, gwc *gatewayConfig) {
+			if familyOf(endpointIP) != ipFamilyV6 || !hasEgressIPv6 {
+				return
+			}
+
+			policyKey := egressmap.NewEgressPolicyKey6(endpointIP, dstCIDR.IP, dstCIDR.Mask)
+			policyVal, policyPresent := egressPolicies[policyKey]
+
+			gatewayIP := gwc.gatewayIP
+			if excludedCIDR {
+				gatewayIP = ExcludedCIDRIPv6
+			}
+
+			if policyPresent && policyVal.Match(egressIPv6.IP, gatewayIP) {
+				return
+			}
+
+			logger := log.WithFields(logrus.Fields{
+				logfields.SourceIP:        endpointIP,
+				logfields.DestinationCIDR: dstCIDR.String(),
+				logfields.EgressIP:        egressIPv6.IP,
+				logfields.GatewayIP:       gatewayIP,
+			})
+
+			if err := manager.policyMap6.Update(endpointIP, *dstCIDR, egressIPv6.IP, gatewayIP); err != nil {
+				logger.WithError(err).Error("Error applying IPv6 egress gateway policy")
+			} else {
+				logger.Debug("IPv6 egress gateway policy applied")
+			}
+		}
+
 		policyConfig.forEachEndpointAndCIDR(addEgressRule)
+
+		// See the matching comment in addMissingEgressRules: resolved
+		// dstFQDNs addresses live in the FQDN resolver's cache, not in
+		// policyConfig.dstCIDRs, so they need installing separately.
+		for _, dstCIDR := range manager.fqdns.get(policyConfig.id) {
+			if familyOf(dstCIDR.IP) != ipFamilyV6 {
+				continue
+			}
+
+			for _, ep := range policyConfig.matchedEndpoints {
+				for _, endpointIP := range ep.ips {
+					addEgressRule(endpointIP, dstCIDR, false, &policyConfig.gatewayConfig)
+				}
+			}
+		}
+	}
+}
+
+// removeUnusedEgressRulesV6 is the IPv6 counterpart of
+// removeUnusedEgressRules.
+func (manager *Manager) removeUnusedEgressRulesV6() {
+	if manager.policyMap6 == nil {
+		return
+	}
+
+	egressPolicies := map[egressmap.EgressPolicyKey6]egressmap.EgressPolicyVal6{}
+	manager.policyMap6.IterateWithCallback(
+		func(key *egressmap.EgressPolicyKey6, val *egressmap.EgressPolicyVal6) {
+			egressPolicies[*key] = *val
+		})
+
+	// addMissingEgressRulesV6 writes each policy's IPv6 egress IP rather
+	// than gwc.egressIP, so match against that, keyed by gatewayConfig
+	egressIPv6ByGateway := map[*gatewayConfig]net.IP{}
+	for id, policyConfig := range manager.policyConfigs {
+		if egressIPv6, ok := manager.policyEgressIPv6[id]; ok {
+			egressIPv6ByGateway[&policyConfig.gatewayConfig] = egressIPv6.IP
+		}
+	}
+
+nextPolicyKey6:
+	for policyKey, policyVal := range egressPolicies {
+		matchPolicy := func(endpointIP net.IP, dstCIDR *net.IPNet, excludedCIDR bool, gwc *gatewayConfig) bool {
+			egressIPv6, ok := egressIPv6ByGateway[gwc]
+			if !ok {
+				return false
+			}
+
+			gatewayIP := gwc.gatewayIP
+			if excludedCIDR {
+				gatewayIP = ExcludedCIDRIPv6
+			}
+
+			return policyKey.Match(endpointIP, dstCIDR) && policyVal.Match(egressIPv6, gatewayIP)
+		}
+
+		if manager.policyMatches(policyKey.GetSourceIP(), matchPolicy) {
+			continue nextPolicyKey6
+		}
+
+		// See removeUnusedEgressRules: FQDN-resolved entries aren't
+		// visited by policyMatches
+		for _, policy := range manager.policyConfigsBySourceIP[policyKey.GetSourceIP().String()] {
+			for _, dstCIDR := range manager.fqdns.get(policy.id) {
+				if matchPolicy(policyKey.GetSourceIP(), dstCIDR, false, &policy.gatewayConfig) {
+					continue nextPolicyKey6
+				}
+			}
+		}
+
+		logger := log.WithFields(logrus.Fields{
+			logfields.SourceIP:        policyKey.GetSourceIP(),
+			logfields.DestinationCIDR: policyKey.GetDestCIDR().String(),
+			logfields.EgressIP:        policyVal.GetEgressIP(),
+			logfields.GatewayIP:       policyVal.GetGatewayIP(),
+		})
+
+		if err := manager.policyMap6.Delete(policyKey.GetSourceIP(), *policyKey.GetDestCIDR()); err != nil {
+			logger.WithError(err).Error("Error removing IPv6 egress gateway policy")
+		} else {
+			logger.Debug("IPv6 egress gateway policy removed")
+		}
 	}
 }
 
@@ -839,7 +1227,14 @@ nextPolicyKey:
 	for policyKey, policyVal := range egressPolicies {
 		matchPolicy := func(endpointIP net.IP, dstCIDR *net.IPNet, excludedCIDR bool, gwc *gatewayConfig) bool {
 			gatewayIP := gwc.gatewayIP
-			if excludedCIDR {
+			switch {
+			case policyVal.GetGatewayIP().Equal(DenyCIDRIPv4):
+				// a Deny policy's entries all point at the same sentinel
+				// regardless of which node is configured as gateway, so
+				// don't let them be reaped just because this node isn't
+				// (or is no longer) the real gateway for the policy
+				gatewayIP = DenyCIDRIPv4
+			case excludedCIDR:
 				gatewayIP = ExcludedCIDRIPv4
 			}
 
@@ -850,6 +1245,17 @@ nextPolicyKey:
 			continue nextPolicyKey
 		}
 
+		// policyMatches only visits dstCIDRs/excludedCIDRs, so also keep
+		// the entries addMissingEgressRules installed for FQDN-resolved
+		// addresses
+		for _, policy := range manager.policyConfigsBySourceIP[policyKey.SourceIP.IP().String()] {
+			for _, dstCIDR := range manager.fqdns.get(policy.id) {
+				if matchPolicy(policyKey.SourceIP.IP(), dstCIDR, false, &policy.gatewayConfig) {
+					continue nextPolicyKey
+				}
+			}
+		}
+
 		logger := log.WithFields(logrus.Fields{
 			logfields.SourceIP:        policyKey.GetSourceIP(),
 			logfields.DestinationCIDR: policyKey.GetDestCIDR().String(),
@@ -880,7 +1286,7 @@ func (manager *Manager) reconcileLocked() {
 		manager.updatePoliciesBySourceIP()
 	}
 
-	if manager.eventBitmapIsSet(eventAddPolicy, eventDeletePolicy) {
+	if manager.eventBitmapIsSet(eventAddPolicy, eventDeletePolicy, eventUpdateFQDN) {
 		manager.updatePoliciesBySourceIP()
 	}
 
@@ -892,13 +1298,27 @@ func (manager *Manager) reconcileLocked() {
 		manager.updatePoliciesBySourceIP()
 	}
 
+	manager.runSchedulerLocked()
+	if manager.updateActiveGatewayStatusLocked() {
+		manager.reconciliationQueue.AddRateLimited(reconciliationReasonStatusRetry)
+	}
 	manager.regenerateGatewayConfigs()
 
 	shouldRetry := manager.addMissingIpRulesAndRoutes(false)
 	manager.removeUnusedIpRulesAndRoutes()
 
 	if shouldRetry {
-		manager.addMissingIpRulesAndRoutes(true)
+		if manager.addMissingIpRulesAndRoutes(true) {
+			// still failing after the immediate retry: hand it off to
+			// the rate-limited workqueue instead of silently dropping it
+			// until an unrelated event re-triggers reconciliation
+			manager.reconciliationQueue.AddRateLimited(reconciliationReasonRouteRetry)
+		}
+	}
+
+	if manager.enableBandwidthManager {
+		manager.addMissingQdiscs()
+		manager.removeUnusedQdiscs()
 	}
 
 	// The order of the next 2 function calls matters, as by first adding missing policies and
@@ -906,6 +1326,11 @@ func (manager *Manager) reconcileLocked() {
 	manager.addMissingEgressRules()
 	manager.removeUnusedEgressRules()
 
+	if manager.enableIPv6 {
+		manager.addMissingEgressRulesV6()
+		manager.removeUnusedEgressRulesV6()
+	}
+
 	// clear the events bitmap
 	manager.eventsBitmap = 0
 }
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cilium/cilium/pkg/hive/cell"
+	"github.com/cilium/cilium/pkg/metrics/metric"
+)
+
+// Metrics holds the egress gateway's Prometheus metrics, registered via
+// the [Cell] so that they share the same lifecycle as the [Manager].
+type Metrics struct {
+	// PoliciesTotal is the number of configured CiliumEgressGatewayPolicy
+	// objects
+	PoliciesTotal metric.Gauge
+
+	// PoliciesWithoutGateway is the number of policies for which no
+	// healthy gateway could currently be selected
+	PoliciesWithoutGateway metric.Gauge
+
+	// EndpointsPending is the depth of the endpoint events workqueue
+	EndpointsPending metric.Gauge
+
+	// ReconciliationDuration is the latency of a single reconcileLocked
+	// pass
+	ReconciliationDuration metric.Histogram
+
+	// BPFMapEntries is the combined number of entries in the IPv4 and
+	// (when enabled) IPv6 egress policy BPF maps, as counted at the start
+	// of the last reconciliation's add pass
+	BPFMapEntries metric.Gauge
+
+	// IPRuleAddErrorsTotal counts failures to add an IP rule/route in
+	// addMissingIpRulesAndRoutes
+	IPRuleAddErrorsTotal metric.Counter
+
+	// BandwidthPacedBytesTotal counts bytes transmitted (rather than
+	// dropped) through a bandwidth-shaped egress gateway policy's
+	// interface, labeled by policy. When more than one policy shares the
+	// interface the total is split evenly across them, since netlink
+	// exposes only one TX counter per interface.
+	BandwidthPacedBytesTotal metric.Vec[metric.Counter]
+
+	// BandwidthDroppedBytesTotal counts packets (the interface's TX
+	// dropped-packet counter, not bytes) dropped on a bandwidth-shaped
+	// egress gateway policy's interface, labeled by policy, with the same
+	// even split across policies sharing an interface as
+	// BandwidthPacedBytesTotal.
+	BandwidthDroppedBytesTotal metric.Vec[metric.Counter]
+
+	// ReconciliationQueueDepth is the current depth of the
+	// reconciliationQueue workqueue
+	ReconciliationQueueDepth metric.Gauge
+
+	// ReconciliationRetriesTotal counts reconciliation sub-step retries,
+	// labeled by reason (e.g. "route-retry")
+	ReconciliationRetriesTotal metric.Vec[metric.Counter]
+}
+
+// NewMetrics creates the egress gateway's Prometheus metrics using the
+// standard Cilium metric namespace/subsystem convention.
+func NewMetrics() Metrics {
+	return Metrics{
+		PoliciesTotal: metric.NewGauge(metric.GaugeOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "policies_total",
+			Help:      "Number of configured CiliumEgressGatewayPolicy objects",
+		}),
+		PoliciesWithoutGateway: metric.NewGauge(metric.GaugeOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "policies_without_gateway",
+			Help:      "Number of policies for which no gateway could be selected",
+		}),
+		EndpointsPending: metric.NewGauge(metric.GaugeOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "endpoints_pending",
+			Help:      "Number of CiliumEndpoint events waiting to be processed",
+		}),
+		ReconciliationDuration: metric.NewHistogram(metric.HistogramOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "reconciliation_duration_seconds",
+			Help:      "Duration of an egress gateway reconciliation pass",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		BPFMapEntries: metric.NewGauge(metric.GaugeOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "bpf_map_entries",
+			Help:      "Number of entries in the egress gateway policy BPF map",
+		}),
+		IPRuleAddErrorsTotal: metric.NewCounter(metric.CounterOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "ip_rule_add_errors_total",
+			Help:      "Number of failed attempts to add an egress gateway IP rule/route",
+		}),
+		BandwidthPacedBytesTotal: metric.NewCounterVec(metric.CounterOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "bandwidth_paced_bytes_total",
+			Help:      "Number of bytes transmitted on the egress interface of a bandwidth-shaped egress gateway policy",
+		}, []string{"policy"}),
+		BandwidthDroppedBytesTotal: metric.NewCounterVec(metric.CounterOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "bandwidth_dropped_bytes_total",
+			Help:      "Number of packets dropped on the egress interface of a bandwidth-shaped egress gateway policy",
+		}, []string{"policy"}),
+		ReconciliationQueueDepth: metric.NewGauge(metric.GaugeOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "reconciliation_queue_depth",
+			Help:      "Depth of the egress gateway reconciliation retry workqueue",
+		}),
+		ReconciliationRetriesTotal: metric.NewCounterVec(metric.CounterOpts{
+			Namespace: "cilium",
+			Subsystem: "egress_gateway",
+			Name:      "reconciliation_retries_total",
+			Help:      "Number of egress gateway reconciliation sub-step retries, by reason",
+		}, []string{"reason"}),
+	}
+}
+
+// observeReconciliation records the duration of a reconcileLocked pass and
+// refreshes the policy/endpoint gauges.
+func (manager *Manager) observeReconciliation(start time.Time) {
+	if manager.metrics == nil {
+		return
+	}
+
+	manager.metrics.ReconciliationDuration.Observe(time.Since(start).Seconds())
+	manager.metrics.PoliciesTotal.Set(float64(len(manager.policyConfigs)))
+	manager.metrics.EndpointsPending.Set(float64(manager.endpointEventsQueue.Len()))
+	manager.metrics.ReconciliationQueueDepth.Set(float64(manager.reconciliationQueue.Len()))
+	manager.metrics.BPFMapEntries.Set(float64(manager.bpfMapEntries))
+
+	withoutGateway := 0
+	for _, policyConfig := range manager.policyConfigs {
+		if !policyConfig.gatewayConfig.localNodeConfiguredAsGateway && policyConfig.gatewayConfig.gatewayIP == nil {
+			withoutGateway++
+		}
+	}
+	manager.metrics.PoliciesWithoutGateway.Set(float64(withoutGateway))
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"sort"
+
+	"github.com/cilium/cilium/pkg/logging/logfields"
+	nodeTypes "github.com/cilium/cilium/pkg/node/types"
+)
+
+// schedulerInput is the per-policy input to scheduleGateways: the policy's
+// identity, its candidate gateway nodes sorted in a stable order (e.g. by
+// node name), and the maximum number of egress IPs any one of those nodes
+// may be assigned across all policies (0 means unlimited).
+type schedulerInput struct {
+	id                  policyID
+	candidates          []nodeTypes.Node
+	maxEgressIPsPerNode int
+}
+
+// scheduleGateways computes, for every policy in inputs, the gateway node
+// it should be assigned, deterministically: every agent that calls this
+// function with the same inputs (the same policies, in the same order,
+// with the same candidate lists) gets back the exact same
+// map[policyID]nodeTypes.Node, regardless of goroutine scheduling.
+//
+// Policies are visited in a stable order (by policy name), and for each
+// one the candidate maximizing selectGateway's rendezvous score for that
+// policy, among those whose running assigned count is still below
+// maxEgressIPsPerNode, is picked; if none fits, the policy is left
+// unscheduled. Ranking candidates by rendezvous score instead of by
+// static sort order means that adding or removing a candidate only
+// reshuffles the policies that hashed highest to it, rather than the
+// whole assignment.
+func scheduleGateways(inputs []schedulerInput) (assignments map[policyID]nodeTypes.Node, unscheduled []policyID) {
+	sorted := make([]schedulerInput, len(inputs))
+	copy(sorted, inputs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].id.Name < sorted[j].id.Name
+	})
+
+	assignments = make(map[policyID]nodeTypes.Node, len(sorted))
+	assignedCount := make(map[string]int)
+
+	for _, in := range sorted {
+		selected, ok := scheduleOne(in, assignedCount)
+		if !ok {
+			unscheduled = append(unscheduled, in.id)
+			continue
+		}
+
+		assignments[in.id] = selected
+		assignedCount[selected.Name]++
+	}
+
+	return assignments, unscheduled
+}
+
+// runSchedulerLocked builds the scheduler input from the manager's current
+// policies and healthy nodes and recomputes scheduledGateways, which
+// updateActiveGatewayStatusLocked then reports through statusUpdater. It
+// must be called with manager's lock held.
+func (manager *Manager) runSchedulerLocked() {
+	inputs := make([]schedulerInput, 0, len(manager.policyConfigs))
+
+	for id, policyConfig := range manager.policyConfigs {
+		var candidates []nodeTypes.Node
+		for _, node := range manager.nodes {
+			if manager.isGatewayHealthy(node.Name) {
+				candidates = append(candidates, node)
+			}
+		}
+
+		inputs = append(inputs, schedulerInput{
+			id:                  id,
+			candidates:          candidates,
+			maxEgressIPsPerNode: policyConfig.maxEgressIPsPerNode,
+		})
+	}
+
+	assignments, unscheduled := scheduleGateways(inputs)
+	manager.scheduledGateways = assignments
+
+	for _, id := range unscheduled {
+		log.WithField(logfields.CiliumEgressGatewayPolicyName, id.Name).
+			Warn("No candidate gateway node available within capacity caps for egress gateway policy")
+	}
+}
+
+// scheduleOne picks, via selectGateway, the candidate of in that
+// maximizes the rendezvous score for in.id among those whose current
+// assigned count, per assignedCount, is still below
+// in.maxEgressIPsPerNode.
+func scheduleOne(in schedulerInput, assignedCount map[string]int) (nodeTypes.Node, bool) {
+	fitting := make([]nodeTypes.Node, 0, len(in.candidates))
+	for _, candidate := range in.candidates {
+		if in.maxEgressIPsPerNode > 0 && assignedCount[candidate.Name] >= in.maxEgressIPsPerNode {
+			continue
+		}
+
+		fitting = append(fitting, candidate)
+	}
+
+	return selectGateway(fitting, []byte(in.id.Name))
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	nodeTypes "github.com/cilium/cilium/pkg/node/types"
+)
+
+func genSchedulerInputs(numPolicies, numNodes, maxEgressIPsPerNode int) []schedulerInput {
+	nodes := make([]nodeTypes.Node, numNodes)
+	for i := range nodes {
+		nodes[i] = nodeTypes.Node{Name: fmt.Sprintf("node-%04d", i)}
+	}
+
+	inputs := make([]schedulerInput, numPolicies)
+	for i := range inputs {
+		inputs[i] = schedulerInput{
+			id:                  policyID{Name: fmt.Sprintf("policy-%04d", i)},
+			candidates:          nodes,
+			maxEgressIPsPerNode: maxEgressIPsPerNode,
+		}
+	}
+
+	return inputs
+}
+
+// TestScheduleGatewaysDeterministic asserts that scheduleGateways returns
+// the exact same assignment regardless of the order its goroutine happens
+// to observe the (already generated) inputs slice in, which is the
+// property every agent in the cluster relies on to agree without
+// communicating.
+func TestScheduleGatewaysDeterministic(t *testing.T) {
+	inputs := genSchedulerInputs(200, 20, 5)
+
+	want, wantUnscheduled := scheduleGateways(inputs)
+
+	var wg sync.WaitGroup
+	for run := 0; run < 50; run++ {
+		shuffled := make([]schedulerInput, len(inputs))
+		copy(shuffled, inputs)
+		rand.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+
+		wg.Add(1)
+		go func(shuffled []schedulerInput) {
+			defer wg.Done()
+
+			got, gotUnscheduled := scheduleGateways(shuffled)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("scheduleGateways is not deterministic under reordering: got %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(gotUnscheduled, wantUnscheduled) {
+				t.Errorf("scheduleGateways unscheduled set is not deterministic under reordering: got %v, want %v", gotUnscheduled, wantUnscheduled)
+			}
+		}(shuffled)
+	}
+	wg.Wait()
+}
+
+// TestScheduleGatewaysRespectsCapacityCap asserts that no node is ever
+// assigned more policies than maxEgressIPsPerNode allows.
+func TestScheduleGatewaysRespectsCapacityCap(t *testing.T) {
+	const cap = 3
+	inputs := genSchedulerInputs(50, 5, cap)
+
+	assignments, unscheduled := scheduleGateways(inputs)
+
+	counts := map[string]int{}
+	for _, node := range assignments {
+		counts[node.Name]++
+	}
+
+	for name, count := range counts {
+		if count > cap {
+			t.Errorf("node %s was assigned %d policies, want at most %d", name, count, cap)
+		}
+	}
+
+	if len(assignments)+len(unscheduled) != len(inputs) {
+		t.Errorf("got %d assignments + %d unscheduled, want %d total", len(assignments), len(unscheduled), len(inputs))
+	}
+}
+
+func BenchmarkScheduleGateways1000x1000(b *testing.B) {
+	inputs := genSchedulerInputs(1000, 1000, 0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scheduleGateways(inputs)
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+// CRDStatusUpdater pushes the currently active gateway node for an egress
+// gateway policy back to that CiliumEgressGatewayPolicy's
+// status.activeGatewayNode field, so that failover events triggered by
+// runGatewayHealthChecker are visible to users via kubectl rather than
+// only through logs and metrics. It is injected as an optional cell
+// dependency; Manager skips status reporting entirely if none is provided.
+type CRDStatusUpdater interface {
+	UpdateActiveGatewayNode(policyName string, nodeName string) error
+}
+
+// updateActiveGatewayStatusLocked pushes the scheduler's current gateway
+// assignment to statusUpdater for every policy whose active gateway
+// changed since the last reconciliation, and clears the status of
+// policies that lost their gateway (e.g. all candidates turned
+// unhealthy). It must be called with manager's lock held, after
+// runSchedulerLocked has refreshed scheduledGateways.
+//
+// A failed push is left in activeGatewayNodes as stale, and
+// updateActiveGatewayStatusLocked returns true so the caller can requeue
+// the retry itself instead of only refreshing on the next unrelated
+// reconciliation.
+func (manager *Manager) updateActiveGatewayStatusLocked() (failed bool) {
+	if manager.statusUpdater == nil {
+		return false
+	}
+
+	for id, node := range manager.scheduledGateways {
+		if manager.activeGatewayNodes[id] == node.Name {
+			continue
+		}
+
+		if err := manager.statusUpdater.UpdateActiveGatewayNode(id.Name, node.Name); err != nil {
+			log.WithFields(logrus.Fields{
+				logfields.CiliumEgressGatewayPolicyName: id.Name,
+				logfields.NodeName:                      node.Name,
+			}).WithError(err).Warn("Failed to update egress gateway policy status")
+			failed = true
+			continue
+		}
+
+		manager.activeGatewayNodes[id] = node.Name
+	}
+
+	for id := range manager.activeGatewayNodes {
+		if _, scheduled := manager.scheduledGateways[id]; scheduled {
+			continue
+		}
+
+		if err := manager.statusUpdater.UpdateActiveGatewayNode(id.Name, ""); err != nil {
+			log.WithField(logfields.CiliumEgressGatewayPolicyName, id.Name).
+				WithError(err).Warn("Failed to clear egress gateway policy status")
+			failed = true
+			continue
+		}
+
+		delete(manager.activeGatewayNodes, id)
+	}
+
+	return failed
+}
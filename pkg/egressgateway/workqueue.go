@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressgateway
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cilium/cilium/pkg/logging/logfields"
+)
+
+// reconciliationReason identifies which failing reconcileLocked sub-step a
+// reconciliationQueue item represents, so runReconciliationQueue knows
+// which step to retry.
+const (
+	reconciliationReasonRouteRetry           = "route-retry"
+	reconciliationReasonGatewayHealthChanged = "gateway-health-changed"
+	reconciliationReasonStatusRetry          = "status-retry"
+)
+
+// runReconciliationQueue spawns a goroutine draining reconciliationQueue:
+// for every reason it pops, it retries the corresponding reconcileLocked
+// sub-step. A sub-step that fails again is requeued with
+// AddRateLimited, so it backs off exponentially instead of spinning; one
+// that succeeds calls Forget so its backoff resets.
+func (manager *Manager) runReconciliationQueue(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		go func() {
+			<-ctx.Done()
+			manager.reconciliationQueue.ShutDown()
+		}()
+
+		for {
+			item, shutdown := manager.reconciliationQueue.Get()
+			if shutdown {
+				return
+			}
+
+			reason := item.(string)
+			manager.retryReconciliationStep(reason)
+		}
+	}()
+}
+
+// retryReconciliationStep retries the reconcileLocked sub-step identified
+// by reason. Sub-steps that can fail again (route installation, CRD status
+// updates) are
+// re-enqueued with backoff; reconciliationReasonGatewayHealthChanged just
+// triggers a full reconciliation through reconciliationTrigger and is
+// always forgotten, since there is nothing to retry beyond that.
+func (manager *Manager) retryReconciliationStep(reason string) {
+	defer manager.reconciliationQueue.Done(reason)
+
+	if manager.metrics != nil {
+		manager.metrics.ReconciliationRetriesTotal.WithLabelValues(reason).Inc()
+	}
+
+	switch reason {
+	case reconciliationReasonRouteRetry:
+		manager.Lock()
+		stillFailing := manager.addMissingIpRulesAndRoutes(true)
+		manager.Unlock()
+
+		if stillFailing {
+			manager.reconciliationQueue.AddRateLimited(reason)
+		} else {
+			manager.reconciliationQueue.Forget(reason)
+		}
+	case reconciliationReasonGatewayHealthChanged:
+		manager.reconciliationTrigger.TriggerWithReason("gateway health changed")
+		manager.reconciliationQueue.Forget(reason)
+	case reconciliationReasonStatusRetry:
+		manager.Lock()
+		stillFailing := manager.updateActiveGatewayStatusLocked()
+		manager.Unlock()
+
+		if stillFailing {
+			manager.reconciliationQueue.AddRateLimited(reason)
+		} else {
+			manager.reconciliationQueue.Forget(reason)
+		}
+	default:
+		log.WithField(logfields.Reason, reason).Warn("Unknown egress gateway reconciliation retry reason")
+		manager.reconciliationQueue.Forget(reason)
+	}
+}
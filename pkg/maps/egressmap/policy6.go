@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressmap
+
+import "net"
+
+// EgressPolicyKey6 is the IPv6 counterpart of EgressPolicyKey4: it matches
+// a (source endpoint IP, destination CIDR) tuple.
+type EgressPolicyKey6 struct {
+	SourceIP  IPv6
+	DestCIDR  IPv6
+	PrefixLen uint32
+}
+
+// NewEgressPolicyKey6 creates the IPv6 egress policy map key matching
+// traffic from endpointIP to the dstCIDRIP/dstCIDRMask network.
+func NewEgressPolicyKey6(endpointIP, dstCIDRIP net.IP, dstCIDRMask net.IPMask) EgressPolicyKey6 {
+	ones, _ := dstCIDRMask.Size()
+
+	return EgressPolicyKey6{
+		SourceIP:  NewIPv6(endpointIP),
+		DestCIDR:  NewIPv6(dstCIDRIP),
+		PrefixLen: uint32(ones),
+	}
+}
+
+// Match returns true if the key matches the given source IP and
+// destination CIDR.
+func (k *EgressPolicyKey6) Match(endpointIP net.IP, dstCIDR *net.IPNet) bool {
+	ones, _ := dstCIDR.Mask.Size()
+	return k.SourceIP.IP().Equal(endpointIP) && k.DestCIDR.IP().Equal(dstCIDR.IP) && int(k.PrefixLen) == ones
+}
+
+// GetSourceIP returns the key's source endpoint IP.
+func (k *EgressPolicyKey6) GetSourceIP() net.IP {
+	return k.SourceIP.IP()
+}
+
+// GetDestCIDR returns the key's destination CIDR.
+func (k *EgressPolicyKey6) GetDestCIDR() *net.IPNet {
+	return &net.IPNet{
+		IP:   k.DestCIDR.IP(),
+		Mask: net.CIDRMask(int(k.PrefixLen), 128),
+	}
+}
+
+// EgressPolicyVal6 is the IPv6 counterpart of EgressPolicyVal4: it records
+// the egress IP and gateway IP to use for traffic matching the associated
+// EgressPolicyKey6.
+type EgressPolicyVal6 struct {
+	EgressIP  IPv6
+	GatewayIP IPv6
+}
+
+// Match returns true if the value already records the given egress and
+// gateway IPs.
+func (v *EgressPolicyVal6) Match(egressIP, gatewayIP net.IP) bool {
+	return v.EgressIP.IP().Equal(egressIP) && v.GatewayIP.IP().Equal(gatewayIP)
+}
+
+// GetEgressIP returns the value's egress IP.
+func (v *EgressPolicyVal6) GetEgressIP() net.IP {
+	return v.EgressIP.IP()
+}
+
+// GetGatewayIP returns the value's gateway IP.
+func (v *EgressPolicyVal6) GetGatewayIP() net.IP {
+	return v.GatewayIP.IP()
+}
+
+// IPv6 is a fixed-size, BPF-map-friendly encoding of a 128-bit IP address.
+type IPv6 [16]byte
+
+// NewIPv6 encodes ip, a 16-byte net.IP, into an IPv6.
+func NewIPv6(ip net.IP) IPv6 {
+	var v6 IPv6
+	copy(v6[:], ip.To16())
+	return v6
+}
+
+// IP decodes the IPv6 back into a net.IP.
+func (v IPv6) IP() net.IP {
+	return net.IP(v[:])
+}
+
+// PolicyMap6 is the IPv6 counterpart of PolicyMap: it communicates the
+// active IPv6 egress gateway policies to the datapath.
+type PolicyMap6 interface {
+	Update(endpointIP net.IP, dstCIDR net.IPNet, egressIP, gatewayIP net.IP) error
+	Delete(endpointIP net.IP, dstCIDR net.IPNet) error
+	IterateWithCallback(cb EgressPolicyIterateCallback6) error
+}
+
+// EgressPolicyIterateCallback6 is the signature of the callback passed to
+// PolicyMap6.IterateWithCallback.
+type EgressPolicyIterateCallback6 func(*EgressPolicyKey6, *EgressPolicyVal6)
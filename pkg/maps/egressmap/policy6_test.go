@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package egressmap
+
+import (
+	"net"
+	"testing"
+)
+
+func TestEgressPolicyKey6MatchIPv6(t *testing.T) {
+	endpointIP := net.ParseIP("fd00::1")
+	_, dstCIDR, err := net.ParseCIDR("fd00:cafe::/64")
+	if err != nil {
+		t.Fatalf("failed to parse CIDR: %v", err)
+	}
+
+	key := NewEgressPolicyKey6(endpointIP, dstCIDR.IP, dstCIDR.Mask)
+
+	if !key.Match(endpointIP, dstCIDR) {
+		t.Errorf("expected key %+v to match (%s, %s)", key, endpointIP, dstCIDR)
+	}
+
+	otherEndpointIP := net.ParseIP("fd00::2")
+	if key.Match(otherEndpointIP, dstCIDR) {
+		t.Errorf("expected key %+v not to match (%s, %s)", key, otherEndpointIP, dstCIDR)
+	}
+
+	if got := key.GetSourceIP(); !got.Equal(endpointIP) {
+		t.Errorf("GetSourceIP() = %s, want %s", got, endpointIP)
+	}
+	if got := key.GetDestCIDR(); got.String() != dstCIDR.String() {
+		t.Errorf("GetDestCIDR() = %s, want %s", got, dstCIDR)
+	}
+}
+
+func TestEgressPolicyVal6MatchIPv6(t *testing.T) {
+	egressIP := net.ParseIP("fd00:1234::1")
+	gatewayIP := net.ParseIP("fd00:1234::2")
+
+	val := EgressPolicyVal6{EgressIP: NewIPv6(egressIP), GatewayIP: NewIPv6(gatewayIP)}
+
+	if !val.Match(egressIP, gatewayIP) {
+		t.Errorf("expected val %+v to match (%s, %s)", val, egressIP, gatewayIP)
+	}
+
+	if val.Match(gatewayIP, egressIP) {
+		t.Errorf("expected val %+v not to match swapped IPs", val)
+	}
+
+	if got := val.GetEgressIP(); !got.Equal(egressIP) {
+		t.Errorf("GetEgressIP() = %s, want %s", got, egressIP)
+	}
+	if got := val.GetGatewayIP(); !got.Equal(gatewayIP) {
+		t.Errorf("GetGatewayIP() = %s, want %s", got, gatewayIP)
+	}
+}